@@ -0,0 +1,96 @@
+package ratelimiter
+
+import (
+	"context"
+
+	"golang.org/x/sync/errgroup"
+)
+
+var (
+	_ Strategy = &CompositeStrategy{}
+)
+
+// Tier pairs a Strategy with the Request it should be evaluated against. CompositeStrategy
+// runs every tier's Request against its own Strategy, so each tier needs its own Key (e.g. the
+// same client key suffixed with the rate it enforces) to keep its window from interfering with
+// the others, even when several tiers share the same underlying Store.
+type Tier struct {
+	Strategy Strategy
+	Request  *Request
+}
+
+// CompositeStrategy enforces several tiers against the same client at once, e.g. "60 req/min
+// AND 1000 req/hour AND 10000 req/day", denying the request if any single tier would deny it.
+type CompositeStrategy struct {
+	tiers []Tier
+}
+
+// NewCompositeStrategy creates a CompositeStrategy that enforces every tier in `tiers`.
+func NewCompositeStrategy(tiers ...Tier) *CompositeStrategy {
+	return &CompositeStrategy{tiers: tiers}
+}
+
+// Run evaluates every tier concurrently via an errgroup. If any tier denies the request, Run
+// returns the most restrictive Result across all tiers and best-effort rolls back the tiers
+// that had already recorded the request, so a client isn't double-penalized for a request that
+// was only blocked by a coarser tier. The `r` argument is unused: each tier already carries the
+// Request it should be run with.
+func (s *CompositeStrategy) Run(ctx context.Context, _ *Request) (*Result, error) {
+	results := make([]*Result, len(s.tiers))
+
+	g, gctx := errgroup.WithContext(ctx)
+	for i, tier := range s.tiers {
+		i, tier := i, tier
+		g.Go(func() error {
+			result, err := tier.Strategy.Run(gctx, tier.Request)
+			if err != nil {
+				return err
+			}
+			results[i] = result
+			return nil
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	worst := mostRestrictive(results)
+	if worst.State == Deny {
+		for _, result := range results {
+			if result == worst || result.State != Allow || result.rollback == nil {
+				continue
+			}
+			// best-effort: a tier failing to undo its own counter just costs that tier one
+			// extra counted request, it must never fail the overall composite check.
+			_ = result.rollback(ctx)
+		}
+	}
+
+	return worst, nil
+}
+
+// mostRestrictive picks the Result that would keep a client waiting the longest: any Deny beats
+// every Allow. Among two Denies, the one that recovers *last* wins, since that's the tier that
+// is actually still blocking the client once the others have rolled over. Among two Allows,
+// the one that resets *soonest* wins, since that's the tier closest to denying the next request.
+func mostRestrictive(results []*Result) *Result {
+	worst := results[0]
+	for _, result := range results[1:] {
+		switch {
+		case result.State == Deny && worst.State != Deny:
+			worst = result
+		case result.State != Deny && worst.State == Deny:
+			continue
+		case result.State == Deny && worst.State == Deny:
+			if result.ExpiresAt.After(worst.ExpiresAt) {
+				worst = result
+			}
+		default:
+			if result.ExpiresAt.Before(worst.ExpiresAt) {
+				worst = result
+			}
+		}
+	}
+	return worst
+}
@@ -0,0 +1,99 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMostRestrictiveDenyPicksTheLatestRecoveringTier(t *testing.T) {
+	now := time.Now()
+	soon := &Result{State: Deny, ExpiresAt: now.Add(time.Second)}
+	later := &Result{State: Deny, ExpiresAt: now.Add(time.Hour)}
+
+	if worst := mostRestrictive([]*Result{soon, later}); worst != later {
+		t.Fatalf("expected the tier that recovers last (%v) to win, got %v", later.ExpiresAt, worst.ExpiresAt)
+	}
+	if worst := mostRestrictive([]*Result{later, soon}); worst != later {
+		t.Fatalf("expected the tier that recovers last (%v) to win regardless of order, got %v", later.ExpiresAt, worst.ExpiresAt)
+	}
+}
+
+func TestMostRestrictiveAllowPicksTheSoonestReset(t *testing.T) {
+	now := time.Now()
+	soon := &Result{State: Allow, ExpiresAt: now.Add(time.Second)}
+	later := &Result{State: Allow, ExpiresAt: now.Add(time.Hour)}
+
+	if worst := mostRestrictive([]*Result{soon, later}); worst != soon {
+		t.Fatalf("expected the tier that resets soonest (%v) to win, got %v", soon.ExpiresAt, worst.ExpiresAt)
+	}
+}
+
+func TestMostRestrictiveDenyAlwaysBeatsAllow(t *testing.T) {
+	now := time.Now()
+	allow := &Result{State: Allow, ExpiresAt: now.Add(time.Millisecond)}
+	deny := &Result{State: Deny, ExpiresAt: now.Add(time.Hour)}
+
+	if worst := mostRestrictive([]*Result{allow, deny}); worst != deny {
+		t.Fatal("expected a Deny to win over an Allow even with a later ExpiresAt")
+	}
+}
+
+// fakeStrategy returns a scripted sequence of Results, one per call, so tests can exercise
+// CompositeStrategy without needing a real Store-backed Strategy.
+type fakeStrategy struct {
+	results []*Result
+	calls   int
+}
+
+func (f *fakeStrategy) Run(ctx context.Context, r *Request) (*Result, error) {
+	result := f.results[f.calls]
+	f.calls++
+	return result, nil
+}
+
+func TestCompositeStrategyRollsBackAllowedTiersWhenAnotherDenies(t *testing.T) {
+	rolledBack := false
+	allowedTier := &fakeStrategy{results: []*Result{{
+		State: Allow,
+		rollback: func(ctx context.Context) error {
+			rolledBack = true
+			return nil
+		},
+	}}}
+	denyingTier := &fakeStrategy{results: []*Result{{State: Deny, ExpiresAt: time.Now().Add(time.Hour)}}}
+
+	composite := NewCompositeStrategy(
+		Tier{Strategy: allowedTier, Request: &Request{Key: "a", Limit: 1, Duration: time.Minute}},
+		Tier{Strategy: denyingTier, Request: &Request{Key: "a:d", Limit: 1, Duration: time.Hour}},
+	)
+
+	result, err := composite.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.State != Deny {
+		t.Fatalf("expected the composite to Deny, got %v", result.State)
+	}
+	if !rolledBack {
+		t.Fatal("expected the allowed tier to be rolled back after another tier denied")
+	}
+}
+
+func TestCompositeStrategyAllowsWhenEveryTierAllows(t *testing.T) {
+	tierA := &fakeStrategy{results: []*Result{{State: Allow, ExpiresAt: time.Now().Add(time.Minute)}}}
+	tierB := &fakeStrategy{results: []*Result{{State: Allow, ExpiresAt: time.Now().Add(time.Hour)}}}
+
+	composite := NewCompositeStrategy(
+		Tier{Strategy: tierA, Request: &Request{Key: "a", Limit: 1, Duration: time.Minute}},
+		Tier{Strategy: tierB, Request: &Request{Key: "a:h", Limit: 1, Duration: time.Hour}},
+	)
+
+	result, err := composite.Run(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.State != Allow {
+		t.Fatalf("expected the composite to Allow, got %v", result.State)
+	}
+}
@@ -0,0 +1,140 @@
+package ratelimiter
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	_ Strategy = &gcraStrategy{}
+)
+
+// gcraScript implements the Generic Cell Rate Algorithm as a Script so it works unmodified
+// against either a redis-backed Store or a MemoryStore: the read-modify-write of the
+// "theoretical arrival time" (TAT) has to be atomic, which `Lua` gets from redis `EVAL` and
+// `Exec` gets from the MemoryStore holding the key's shard lock for the duration of the call.
+//
+// KEYS[1]/the locked key holds the TAT for this client, in milliseconds since the epoch.
+// ARGV[1]/args[0] is the emission interval T, in milliseconds.
+// ARGV[2]/args[1] is the burst tolerance tau, in milliseconds.
+//
+// Both implementations reply with a 3-tuple of (allowed, now, tat_or_allow_at), all in
+// milliseconds since the epoch: `now` is the clock the script used, and the third value is
+// the new TAT when allowed, or the time the request would next be allowed when denied.
+var gcraScript = Script{
+	Lua: `
+local tat = tonumber(redis.call('GET', KEYS[1]))
+local time = redis.call('TIME')
+local now = math.floor(tonumber(time[1]) * 1000 + tonumber(time[2]) / 1000)
+
+if tat == nil then
+	tat = now
+end
+
+local emission_interval = tonumber(ARGV[1])
+local burst_tolerance = tonumber(ARGV[2])
+
+local new_tat = math.max(tat, now) + emission_interval
+local allow_at = new_tat - burst_tolerance
+
+if now < allow_at then
+	return {0, now, allow_at}
+end
+
+redis.call('SET', KEYS[1], new_tat, 'PX', new_tat - now)
+return {1, now, new_tat}
+`,
+	Exec: func(now time.Time, get func() (string, bool), set func(value string, ttl time.Duration), args []interface{}) []interface{} {
+		emissionInterval := args[0].(int64)
+		burstTolerance := args[1].(int64)
+		nowMillis := now.UnixMilli()
+
+		tat := nowMillis
+		if value, ok := get(); ok {
+			if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+				tat = parsed
+			}
+		}
+		if tat < nowMillis {
+			tat = nowMillis
+		}
+
+		newTAT := tat + emissionInterval
+		allowAt := newTAT - burstTolerance
+
+		if nowMillis < allowAt {
+			return []interface{}{int64(0), nowMillis, allowAt}
+		}
+
+		set(strconv.FormatInt(newTAT, 10), time.Duration(newTAT-nowMillis)*time.Millisecond)
+		return []interface{}{int64(1), nowMillis, newTAT}
+	},
+}
+
+type gcraStrategy struct {
+	store Store
+}
+
+// NewGCRAStrategy creates a Strategy that implements the Generic Cell Rate Algorithm (GCRA),
+// an alternative to the rolling window sorted-set approach in `counterStrategy`. Instead of
+// keeping one entry per request, GCRA only ever stores a single value per key (the TAT) so
+// memory usage is O(1) per client rather than O(limit), while still enforcing both an average
+// rate (`Limit` requests per `Duration`) and a burst of up to `Limit` requests.
+func NewGCRAStrategy(store Store) *gcraStrategy {
+	return &gcraStrategy{
+		store: store,
+	}
+}
+
+// Run evaluates the request against the GCRA. The emission interval `T` is `Duration/Limit`,
+// the time it should take on average between two requests, and the burst tolerance `tau` is
+// `Duration`, meaning a client can burst up to `Limit` requests before being throttled back
+// down to the steady rate. Both values, together with the clock, are handled atomically by the
+// Store so the check-and-set never races with another request for the same key.
+func (s *gcraStrategy) Run(ctx context.Context, r *Request) (*Result, error) {
+	if r.Limit == 0 {
+		return nil, errors.Errorf("invalid request for key %v: limit must be greater than zero", r.Key)
+	}
+
+	emissionInterval := r.Duration.Milliseconds() / int64(r.Limit)
+	burstTolerance := r.Duration.Milliseconds()
+
+	reply, err := s.store.Eval(ctx, gcraScript, []string{r.Key}, emissionInterval, burstTolerance)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to run gcra script for key: %v", r.Key)
+	}
+
+	allowed := reply[0].(int64)
+	now := time.UnixMilli(reply[1].(int64))
+
+	if allowed == 0 {
+		allowAt := time.UnixMilli(reply[2].(int64))
+		return &Result{
+			State:      Deny,
+			ExpiresAt:  allowAt,
+			RetryAfter: allowAt.Sub(now),
+			Limit:      r.Limit,
+		}, nil
+	}
+
+	newTAT := reply[2].(int64)
+	return &Result{
+		State:     Allow,
+		ExpiresAt: time.UnixMilli(newTAT),
+		Limit:     r.Limit,
+		rollback: func(ctx context.Context) error {
+			// undo this request's bump by subtracting the emission interval back off the TAT
+			// we stored, so a later CompositeStrategy tier denying the request doesn't leave
+			// this key permanently penalized for a request that never actually went through.
+			previousTAT := newTAT - emissionInterval
+			ttl := time.Until(time.UnixMilli(previousTAT))
+			if ttl <= 0 {
+				ttl = time.Millisecond
+			}
+			return s.store.Set(ctx, r.Key, strconv.FormatInt(previousTAT, 10), ttl)
+		},
+	}, nil
+}
@@ -0,0 +1,75 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGCRAStrategyAllowsUpToBurstThenDenies(t *testing.T) {
+	strategy := NewGCRAStrategy(NewMemoryStore())
+	req := &Request{Key: "client", Limit: 3, Duration: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		result, err := strategy.Run(context.Background(), req)
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		if result.State != Allow {
+			t.Fatalf("request %d: expected Allow, got %v", i, result.State)
+		}
+	}
+
+	result, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.State != Deny {
+		t.Fatalf("expected the 4th request within the burst window to Deny, got %v", result.State)
+	}
+	if result.RetryAfter <= 0 {
+		t.Fatalf("expected a positive RetryAfter on Deny, got %v", result.RetryAfter)
+	}
+}
+
+func TestGCRAStrategyRejectsZeroLimit(t *testing.T) {
+	strategy := NewGCRAStrategy(NewMemoryStore())
+	req := &Request{Key: "client", Limit: 0, Duration: time.Minute}
+
+	if _, err := strategy.Run(context.Background(), req); err == nil {
+		t.Fatal("expected an error for a zero Limit, got nil")
+	}
+}
+
+func TestGCRAStrategyRollbackUndoesTheBump(t *testing.T) {
+	store := NewMemoryStore()
+	strategy := NewGCRAStrategy(store)
+	req := &Request{Key: "client", Limit: 1, Duration: time.Minute}
+
+	allowed, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if allowed.State != Allow {
+		t.Fatalf("expected the first request to Allow, got %v", allowed.State)
+	}
+
+	// without rolling back, the burst is already spent and this would Deny.
+	if denied, err := strategy.Run(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	} else if denied.State != Deny {
+		t.Fatalf("expected the second request to Deny before rollback, got %v", denied.State)
+	}
+
+	if err := allowed.rollback(context.Background()); err != nil {
+		t.Fatalf("unexpected rollback error: %v", err)
+	}
+
+	result, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.State != Allow {
+		t.Fatalf("expected a request to Allow again after rollback, got %v", result.State)
+	}
+}
@@ -0,0 +1,178 @@
+package ratelimiter
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	_ Extractor = &ipExtractor{}
+	_ Extractor = &chainExtractor{}
+)
+
+type ipExtractor struct {
+	trustedProxies   []net.IPNet
+	forwardedHeaders []string
+	ipv4PrefixBits   int
+	ipv6PrefixBits   int
+}
+
+// NewIPExtractor creates an Extractor that resolves the true client IP from the first of
+// `forwardedHeaders` that yields one (typically `X-Forwarded-For`, `X-Real-IP`, or `Forwarded`
+// per RFC 7239), but only when `r.RemoteAddr` itself falls inside one of `trustedProxies`.
+// This stops a client from spoofing its key by setting those headers directly: unless the
+// connection came from a trusted proxy, `r.RemoteAddr` is used as-is.
+func NewIPExtractor(trustedProxies []net.IPNet, forwardedHeaders ...string) *ipExtractor {
+	return &ipExtractor{
+		trustedProxies:   trustedProxies,
+		forwardedHeaders: forwardedHeaders,
+	}
+}
+
+// GroupByIPv4Prefix coarsens the extracted key to the `/bits` IPv4 network the client's address
+// falls in (e.g. `GroupByIPv4Prefix(24)`), so a client can't burn through the limit by rotating
+// through addresses in the same prefix.
+func (e *ipExtractor) GroupByIPv4Prefix(bits int) *ipExtractor {
+	e.ipv4PrefixBits = bits
+	return e
+}
+
+// GroupByIPv6Prefix coarsens the extracted key to the `/bits` IPv6 network the client's address
+// falls in (e.g. `GroupByIPv6Prefix(64)`, the size of the network a single residential customer
+// is commonly assigned), for the same reason as `GroupByIPv4Prefix`.
+func (e *ipExtractor) GroupByIPv6Prefix(bits int) *ipExtractor {
+	e.ipv6PrefixBits = bits
+	return e
+}
+
+// Extract implements Extractor.
+func (e *ipExtractor) Extract(r *http.Request) (string, error) {
+	ip := e.clientIP(r)
+	if ip == nil {
+		return "", errors.Errorf("unable to resolve a client IP from request remote addr %q", r.RemoteAddr)
+	}
+	return e.group(ip), nil
+}
+
+func (e *ipExtractor) clientIP(r *http.Request) net.IP {
+	remote := parseHostIP(r.RemoteAddr)
+	if remote == nil || !e.isTrustedProxy(remote) {
+		return remote
+	}
+
+	for _, header := range e.forwardedHeaders {
+		if ip := ipFromHeader(r, header); ip != nil {
+			return ip
+		}
+	}
+	return remote
+}
+
+func (e *ipExtractor) isTrustedProxy(ip net.IP) bool {
+	for _, network := range e.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *ipExtractor) group(ip net.IP) string {
+	if v4 := ip.To4(); v4 != nil {
+		if e.ipv4PrefixBits > 0 {
+			return v4.Mask(net.CIDRMask(e.ipv4PrefixBits, 32)).String()
+		}
+		return v4.String()
+	}
+
+	if e.ipv6PrefixBits > 0 {
+		return ip.Mask(net.CIDRMask(e.ipv6PrefixBits, 128)).String()
+	}
+	return ip.String()
+}
+
+// ipFromHeader resolves a client IP from a single forwarding header, understanding the three
+// formats in common use: a comma-separated `X-Forwarded-For` list, a bare `X-Real-IP`, and an
+// RFC 7239 `Forwarded` header. We only ever trust a single hop (the proxy at `r.RemoteAddr`
+// itself), so for the list-shaped headers we take the *last* entry, the one that proxy itself
+// appended, rather than the first: every earlier entry was supplied by the client (or a proxy
+// further upstream we have no trust relationship with) and so is trivially spoofable.
+func ipFromHeader(r *http.Request, header string) net.IP {
+	value := r.Header.Get(header)
+	if value == "" {
+		return nil
+	}
+
+	if strings.EqualFold(header, "Forwarded") {
+		return parseForwardedFor(value)
+	}
+
+	parts := strings.Split(value, ",")
+	last := strings.TrimSpace(parts[len(parts)-1])
+	return net.ParseIP(last)
+}
+
+// parseForwardedFor extracts the `for=` parameter from the last element of an RFC 7239
+// `Forwarded` header, e.g. `for=192.0.2.60;proto=http;by=203.0.113.43`, for the same
+// only-trust-the-immediate-proxy reason `ipFromHeader` takes the last `X-Forwarded-For` entry.
+func parseForwardedFor(value string) net.IP {
+	parts := strings.Split(value, ",")
+	last := parts[len(parts)-1]
+
+	for _, pair := range strings.Split(last, ";") {
+		key, val, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "for") {
+			continue
+		}
+
+		val = strings.Trim(strings.TrimSpace(val), `"`)
+		val = strings.TrimPrefix(val, "[")
+		if idx := strings.LastIndex(val, "]"); idx >= 0 {
+			val = val[:idx]
+		} else if host, _, err := net.SplitHostPort(val); err == nil {
+			val = host
+		}
+		return net.ParseIP(val)
+	}
+	return nil
+}
+
+func parseHostIP(hostPort string) net.IP {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		host = hostPort
+	}
+	return net.ParseIP(host)
+}
+
+type chainExtractor struct {
+	extractors []Extractor
+}
+
+// NewChainExtractor creates an Extractor that tries each of `extractors` in order, returning the
+// key from the first one that succeeds. This lets a single middleware instance serve both
+// authenticated requests (e.g. a header-based user ID) and unauthenticated ones (falling back
+// to an IP-based extractor).
+func NewChainExtractor(extractors ...Extractor) *chainExtractor {
+	return &chainExtractor{extractors: extractors}
+}
+
+// Extract implements Extractor.
+func (c *chainExtractor) Extract(r *http.Request) (string, error) {
+	var lastErr error
+	for _, extractor := range c.extractors {
+		key, err := extractor.Extract(r)
+		if err == nil {
+			return key, nil
+		}
+		lastErr = err
+	}
+
+	if lastErr == nil {
+		lastErr = errors.New("no extractors were configured on the chain")
+	}
+	return "", lastErr
+}
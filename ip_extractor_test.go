@@ -0,0 +1,126 @@
+package ratelimiter
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func trustedProxyNets(t *testing.T, cidrs ...string) []net.IPNet {
+	t.Helper()
+	nets := make([]net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("invalid CIDR %q: %v", cidr, err)
+		}
+		nets = append(nets, *network)
+	}
+	return nets
+}
+
+func TestIPExtractorUsesRemoteAddrWhenNotTrusted(t *testing.T) {
+	extractor := NewIPExtractor(trustedProxyNets(t, "10.0.0.0/8"), "X-Forwarded-For")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.5:12345"
+	req.Header.Set("X-Forwarded-For", "9.9.9.9")
+
+	key, err := extractor.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "203.0.113.5" {
+		t.Fatalf("expected the untrusted RemoteAddr to win, got %q", key)
+	}
+}
+
+func TestIPExtractorTrustsTheRightmostForwardedForEntry(t *testing.T) {
+	extractor := NewIPExtractor(trustedProxyNets(t, "10.0.0.0/8"), "X-Forwarded-For")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	// a client-supplied leftmost entry followed by what the trusted proxy itself appended.
+	req.Header.Set("X-Forwarded-For", "9.9.9.9, 203.0.113.5")
+
+	key, err := extractor.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "203.0.113.5" {
+		t.Fatalf("expected the rightmost (proxy-appended) entry 203.0.113.5, got %q", key)
+	}
+}
+
+func TestIPExtractorParsesForwardedHeader(t *testing.T) {
+	extractor := NewIPExtractor(trustedProxyNets(t, "10.0.0.0/8"), "Forwarded")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:12345"
+	req.Header.Set("Forwarded", `for=9.9.9.9;proto=http, for="[2001:db8::1]:4242";proto=http`)
+
+	key, err := extractor.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "2001:db8::1" {
+		t.Fatalf("expected the rightmost for= entry 2001:db8::1, got %q", key)
+	}
+}
+
+func TestIPExtractorGroupsByIPv4Prefix(t *testing.T) {
+	extractor := NewIPExtractor(nil).GroupByIPv4Prefix(24)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.42:12345"
+
+	key, err := extractor.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "203.0.113.0" {
+		t.Fatalf("expected the /24 network address, got %q", key)
+	}
+}
+
+func TestIPExtractorGroupsByIPv6Prefix(t *testing.T) {
+	extractor := NewIPExtractor(nil).GroupByIPv6Prefix(64)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "[2001:db8:abcd:1234::1]:12345"
+
+	key, err := extractor.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "2001:db8:abcd:1234::" {
+		t.Fatalf("expected the /64 network address, got %q", key)
+	}
+}
+
+func TestChainExtractorFallsBackToTheNextExtractor(t *testing.T) {
+	headerExtractor := NewHTTPHeadersExtractor("X-User-Id")
+	ipExtractor := NewIPExtractor(nil)
+	chain := NewChainExtractor(headerExtractor, ipExtractor)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "203.0.113.5:12345"
+
+	key, err := chain.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "203.0.113.5" {
+		t.Fatalf("expected the chain to fall back to the IP extractor, got %q", key)
+	}
+
+	req.Header.Set("X-User-Id", "user-42")
+	key, err = chain.Extract(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if key != "user-42" {
+		t.Fatalf("expected the chain to prefer the header extractor once it succeeds, got %q", key)
+	}
+}
@@ -26,11 +26,23 @@ const (
 // Result represents the response to a check if a client should be rate limited or not.
 // The `State` will be either `Allow` or `Deny`, `TotalRequests` holds the number of specific caller
 // has made over the current period of time and `ExpiresAt` defines when the rate limit will expire/roll over
-// for clients that have gone overt the limit
+// for clients that have gone overt the limit. `RetryAfter` is only populated on `Deny` and holds how
+// long the caller should wait before the request would be allowed, so the HTTP middleware can surface
+// it as a `Retry-After` header.
 type Result struct {
 	State         State
 	TotalRequests uint64
 	ExpiresAt     time.Time
+	RetryAfter    time.Duration
+	// Limit is the `Request.Limit` this Result was evaluated against, so callers that only
+	// have the Result (e.g. the HTTP middleware) can still report how many requests remain.
+	Limit uint64
+
+	// rollback, when set by the Strategy that produced this Result, best-effort undoes the
+	// effect of an Allow. A CompositeStrategy calls it for tiers that already recorded the
+	// request when a different tier ends up denying it, so a client isn't double-penalized
+	// for a request that was only blocked by a coarser tier.
+	rollback func(ctx context.Context) error
 }
 
 // Strategy is the interface the rate limit implementations must implement to be used,
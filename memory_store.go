@@ -0,0 +1,316 @@
+package ratelimiter
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	_ Store     = &memoryStore{}
+	_ Pipeliner = &memoryPipeliner{}
+	_ IntResult = &memoryIntResult{}
+)
+
+// memoryStoreShards controls how many independent locks the store is split across. Keys are
+// hashed into a shard so unrelated clients don't contend on the same mutex.
+const memoryStoreShards = 32
+
+type zsetMember struct {
+	score  float64
+	member string
+}
+
+type memoryItem struct {
+	value     string
+	expiresAt time.Time
+}
+
+func (i memoryItem) expired(now time.Time) bool {
+	return !i.expiresAt.IsZero() && i.expiresAt.Before(now)
+}
+
+type memoryShard struct {
+	mu   sync.Mutex
+	sets map[string][]zsetMember
+	kv   map[string]memoryItem
+}
+
+// memoryStore is an in-process Store backed by a sharded map with a mutex per shard, useful
+// for single-instance deployments or for exercising a Strategy in tests without a real redis.
+type memoryStore struct {
+	shards [memoryStoreShards]*memoryShard
+}
+
+// NewMemoryStore creates a new in-process Store.
+func NewMemoryStore() *memoryStore {
+	store := &memoryStore{}
+	for i := range store.shards {
+		store.shards[i] = &memoryShard{
+			sets: make(map[string][]zsetMember),
+			kv:   make(map[string]memoryItem),
+		}
+	}
+	return store
+}
+
+func (s *memoryStore) shardFor(key string) *memoryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return s.shards[h.Sum32()%memoryStoreShards]
+}
+
+// parseScoreBound parses a sorted-set score bound the way redis does: "-inf"/"+inf" or a
+// plain float, so CountBetween/RemoveRangeByScore accept the same bounds `counterStrategy`
+// already passes to a redis-backed Store.
+func parseScoreBound(value string) (float64, error) {
+	switch value {
+	case sortedSetMin:
+		return math.Inf(-1), nil
+	case sortedSetMax:
+		return math.Inf(1), nil
+	default:
+		return strconv.ParseFloat(value, 64)
+	}
+}
+
+func (s *memoryStore) countBetween(key, min, max string) (int64, error) {
+	lo, err := parseScoreBound(min)
+	if err != nil {
+		return 0, err
+	}
+	hi, err := parseScoreBound(max)
+	if err != nil {
+		return 0, err
+	}
+
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	var count int64
+	for _, m := range shard.sets[key] {
+		if m.score >= lo && m.score <= hi {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (s *memoryStore) addToSet(key string, score float64, member string) (int64, error) {
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	for i, existing := range shard.sets[key] {
+		if existing.member == member {
+			shard.sets[key][i].score = score
+			return 0, nil
+		}
+	}
+	shard.sets[key] = append(shard.sets[key], zsetMember{score: score, member: member})
+	return 1, nil
+}
+
+func (s *memoryStore) removeRangeByScore(key, min, max string) (int64, error) {
+	lo, err := parseScoreBound(min)
+	if err != nil {
+		return 0, err
+	}
+	hi, err := parseScoreBound(max)
+	if err != nil {
+		return 0, err
+	}
+
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	members := shard.sets[key]
+	kept := members[:0]
+	var removed int64
+	for _, m := range members {
+		if m.score >= lo && m.score <= hi {
+			removed++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	shard.sets[key] = kept
+	return removed, nil
+}
+
+func (s *memoryStore) CountBetween(ctx context.Context, key, min, max string) (uint64, error) {
+	count, err := s.countBetween(key, min, max)
+	return uint64(count), err
+}
+
+func (s *memoryStore) AddToSet(ctx context.Context, key string, score float64, member string) error {
+	_, err := s.addToSet(key, score, member)
+	return err
+}
+
+func (s *memoryStore) RemoveRangeByScore(ctx context.Context, key, min, max string) error {
+	_, err := s.removeRangeByScore(key, min, max)
+	return err
+}
+
+func (s *memoryStore) removeMember(key, member string) (int64, error) {
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	members := shard.sets[key]
+	kept := members[:0]
+	var removed int64
+	for _, m := range members {
+		if m.member == member {
+			removed++
+			continue
+		}
+		kept = append(kept, m)
+	}
+	shard.sets[key] = kept
+	return removed, nil
+}
+
+func (s *memoryStore) RemoveMember(ctx context.Context, key, member string) error {
+	_, err := s.removeMember(key, member)
+	return err
+}
+
+func (s *memoryStore) Pipeline() Pipeliner {
+	return &memoryPipeliner{store: s}
+}
+
+func (s *memoryStore) Incr(ctx context.Context, key string) (int64, error) {
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := time.Now()
+	item, ok := shard.kv[key]
+
+	var current int64
+	if ok && !item.expired(now) {
+		parsed, err := strconv.ParseInt(item.value, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "value for key %v is not an integer", key)
+		}
+		current = parsed
+	}
+	current++
+
+	shard.kv[key] = memoryItem{value: strconv.FormatInt(current, 10), expiresAt: item.expiresAt}
+	return current, nil
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (string, error) {
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	item, ok := shard.kv[key]
+	if !ok || item.expired(time.Now()) {
+		return "", ErrKeyNotFound
+	}
+	return item.value, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+	shard.kv[key] = memoryItem{value: value, expiresAt: expiresAt}
+	return nil
+}
+
+// Eval runs `script.Exec` while holding the shard lock for `keys[0]`, which keeps the
+// read-modify-write atomic the same way `script.Lua` does when run against redis.
+func (s *memoryStore) Eval(ctx context.Context, script Script, keys []string, args ...interface{}) ([]interface{}, error) {
+	if len(keys) != 1 {
+		return nil, errors.Errorf("memory store only supports single-key scripts, got %d keys", len(keys))
+	}
+
+	key := keys[0]
+	shard := s.shardFor(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+
+	now := time.Now()
+	get := func() (string, bool) {
+		item, ok := shard.kv[key]
+		if !ok || item.expired(now) {
+			return "", false
+		}
+		return item.value, true
+	}
+	set := func(value string, ttl time.Duration) {
+		var expiresAt time.Time
+		if ttl > 0 {
+			expiresAt = now.Add(ttl)
+		}
+		shard.kv[key] = memoryItem{value: value, expiresAt: expiresAt}
+	}
+
+	return script.Exec(now, get, set, args), nil
+}
+
+type memoryIntResult struct {
+	value int64
+	err   error
+}
+
+func (r *memoryIntResult) Result() (int64, error) {
+	return r.value, r.err
+}
+
+func (r *memoryIntResult) Err() error {
+	return r.err
+}
+
+type memoryPipeliner struct {
+	store *memoryStore
+	ops   []func()
+}
+
+func (p *memoryPipeliner) RemoveRangeByScore(ctx context.Context, key, min, max string) IntResult {
+	result := &memoryIntResult{}
+	p.ops = append(p.ops, func() {
+		result.value, result.err = p.store.removeRangeByScore(key, min, max)
+	})
+	return result
+}
+
+func (p *memoryPipeliner) AddToSet(ctx context.Context, key string, score float64, member string) IntResult {
+	result := &memoryIntResult{}
+	p.ops = append(p.ops, func() {
+		result.value, result.err = p.store.addToSet(key, score, member)
+	})
+	return result
+}
+
+func (p *memoryPipeliner) CountBetween(ctx context.Context, key, min, max string) IntResult {
+	result := &memoryIntResult{}
+	p.ops = append(p.ops, func() {
+		result.value, result.err = p.store.countBetween(key, min, max)
+	})
+	return result
+}
+
+func (p *memoryPipeliner) Exec(ctx context.Context) error {
+	for _, op := range p.ops {
+		op()
+	}
+	return nil
+}
@@ -1,6 +1,8 @@
 package ratelimiter
 
 import (
+	"context"
+	"crypto/subtle"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,6 +13,7 @@ import (
 var (
 	_            *httpRateLimiterHandler = &httpRateLimiterHandler{}
 	_            Extractor               = &httpHeaderExtractor{}
+	_            KeyProvider             = &staticKeyProvider{}
 	stateStrings map[State]string        = map[State]string{
 		Allow: "Allow",
 		Deny:  "Deny",
@@ -21,6 +24,13 @@ const (
 	rateLimitingTotalRequests = "Rate-Limiting-Total-Requests"
 	rateLimitingState         = "Rate-Limiting-State"
 	rateLimitingExpiresAt     = "Rate-Limiting-Expires-At"
+	rateLimitingBypassState   = "Bypass"
+
+	// headers from the draft-ietf-httpapi-ratelimit-headers IETF draft.
+	rateLimitLimit     = "RateLimit-Limit"
+	rateLimitRemaining = "RateLimit-Remaining"
+	rateLimitReset     = "RateLimit-Reset"
+	retryAfter         = "Retry-After"
 )
 
 // Extractor represents the way we will extract a key from an HTTPRequest, this could be
@@ -58,6 +68,50 @@ func NewHTTPHeadersExtractor(headers ...string) Extractor {
 	}
 }
 
+// Bypass decides whether a request should skip rate limiting entirely. It's checked before the
+// key is even extracted, so it can exempt internal services, health checks, or premium tenants
+// without having to wrap the middleware conditionally at the router level.
+type Bypass func(r *http.Request) bool
+
+// KeyProvider supplies the set of API keys an `APIKeyBypass` should accept, so keys can be
+// loaded from a secret store instead of being hardcoded into the config.
+type KeyProvider interface {
+	Keys() []string
+}
+
+type staticKeyProvider struct {
+	keys []string
+}
+
+// Keys implements KeyProvider.
+func (p *staticKeyProvider) Keys() []string {
+	return p.keys
+}
+
+// NewStaticKeyProvider creates a KeyProvider that always returns the same fixed set of keys.
+func NewStaticKeyProvider(keys ...string) KeyProvider {
+	return &staticKeyProvider{keys: keys}
+}
+
+// APIKeyBypass creates a Bypass that exempts a request from rate limiting when the value of
+// `header` matches one of the keys returned by `provider`. Keys are compared in constant time
+// so a client can't use response-timing differences to guess a valid key.
+func APIKeyBypass(header string, provider KeyProvider) Bypass {
+	return func(r *http.Request) bool {
+		value := strings.TrimSpace(r.Header.Get(header))
+		if value == "" {
+			return false
+		}
+
+		for _, key := range provider.Keys() {
+			if subtle.ConstantTimeCompare([]byte(value), []byte(key)) == 1 {
+				return true
+			}
+		}
+		return false
+	}
+}
+
 // RateLimiterConfig holds the basic config we need to create a middleware http.Handler object that
 // performs rate limiting before offloading the request to an actual handler.
 type RateLimiterConfig struct {
@@ -65,6 +119,18 @@ type RateLimiterConfig struct {
 	Strategy    Strategy
 	Expiration  time.Duration
 	MaxRequests uint64
+	// Bypass, when set, is checked before every request and skips rate limiting entirely
+	// when it returns true.
+	Bypass Bypass
+	// Rates, when set, takes precedence over Expiration/MaxRequests: the middleware enforces
+	// every RateSpec as its own tier of a CompositeStrategy (e.g. "60-M" and "1000-H" at once),
+	// all run with Strategy against the extracted key, each tier suffixed so they don't share
+	// a window with one another.
+	Rates []RateSpec
+	// LegacyHeaders, when true, also sends the original `Rate-Limiting-*` headers alongside the
+	// standard `RateLimit-*`/`Retry-After` ones, for clients that haven't migrated yet. A
+	// bypassed request gets `Rate-Limiting-State: Bypass` whatever this is set to.
+	LegacyHeaders bool
 }
 
 type httpRateLimiterHandler struct {
@@ -81,6 +147,33 @@ func NewHTTPRateLimiterHandler(config *RateLimiterConfig) *httpRateLimiterHandle
 	}
 }
 
+// runStrategy evaluates `key` against the configured Strategy. When `Rates` is set it builds a
+// CompositeStrategy on the fly, one tier per RateSpec, each against its own key so the tiers'
+// windows don't interfere with one another even though they share the same Strategy instance.
+func (h *httpRateLimiterHandler) runStrategy(ctx context.Context, key string) (*Result, error) {
+	if len(h.config.Rates) == 0 {
+		return h.config.Strategy.Run(ctx, &Request{
+			Key:      key,
+			Limit:    h.config.MaxRequests,
+			Duration: h.config.Expiration,
+		})
+	}
+
+	tiers := make([]Tier, len(h.config.Rates))
+	for i, rate := range h.config.Rates {
+		tiers[i] = Tier{
+			Strategy: h.config.Strategy,
+			Request: &Request{
+				Key:      fmt.Sprintf("%s:%s", key, rate),
+				Limit:    rate.Limit,
+				Duration: rate.Duration,
+			},
+		}
+	}
+
+	return NewCompositeStrategy(tiers...).Run(ctx, nil)
+}
+
 func (h *httpRateLimiterHandler) writeResponse(writer http.ResponseWriter, status int, msg string, args ...interface{}) {
 	writer.Header().Set("Content-Type", "text/plain")
 	writer.WriteHeader(status)
@@ -95,30 +188,54 @@ func (h *httpRateLimiterHandler) writeResponse(writer http.ResponseWriter, statu
 // it is in terms of rate limiting.
 func (h *httpRateLimiterHandler) RateLimitingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		// a bypass skips rate limiting entirely, but we still want the client to be able to
+		// tell from the response that it wasn't rate limited rather than simply not limited yet.
+		if h.config.Bypass != nil && h.config.Bypass(req) {
+			res.Header().Set(rateLimitingState, rateLimitingBypassState)
+			next.ServeHTTP(res, req)
+			return
+		}
+
 		key, err := h.config.Extractor.Extract(req)
 		if err != nil {
 			h.writeResponse(res, http.StatusBadRequest, "failed to collect rate limiting key from request: %v", err)
 			return
 		}
 
-		result, err := h.config.Strategy.Run(req.Context(), &Request{
-			Key:      key,
-			Limit:    h.config.MaxRequests,
-			Duration: h.config.Expiration,
-		})
+		result, err := h.runStrategy(req.Context(), key)
 
 		if err != nil {
 			h.writeResponse(res, http.StatusInternalServerError, "failed to run rate limiting for request: %v", err)
 			return
 		}
 
-		// set the rate limiting headers both on allor or deny results so the client knows what is going on
-		res.Header().Set(rateLimitingTotalRequests, strconv.FormatUint(result.TotalRequests, 10))
-		res.Header().Set(rateLimitingState, stateStrings[result.State])
-		res.Header().Set(rateLimitingExpiresAt, result.ExpiresAt.Format(time.RFC3339))
+		// set the standard draft-ietf-httpapi-ratelimit-headers headers both on allow and deny
+		// results so the client knows what is going on, regardless of which strategy ran.
+		// a Deny always means zero requests remain, regardless of whether the Strategy that
+		// produced it tracks TotalRequests (GCRA, for instance, never populates it).
+		remaining := uint64(0)
+		if result.State == Allow && result.Limit > result.TotalRequests {
+			remaining = result.Limit - result.TotalRequests
+		}
+		reset := time.Until(result.ExpiresAt)
+		if reset < 0 {
+			reset = 0
+		}
+
+		res.Header().Set(rateLimitLimit, strconv.FormatUint(result.Limit, 10))
+		res.Header().Set(rateLimitRemaining, strconv.FormatUint(remaining, 10))
+		res.Header().Set(rateLimitReset, strconv.FormatInt(int64(reset.Seconds()), 10))
+
+		if h.config.LegacyHeaders {
+			res.Header().Set(rateLimitingTotalRequests, strconv.FormatUint(result.TotalRequests, 10))
+			res.Header().Set(rateLimitingState, stateStrings[result.State])
+			res.Header().Set(rateLimitingExpiresAt, result.ExpiresAt.Format(time.RFC3339))
+		}
 
-		// when the state is `Deny`, just return a 429 response to the client and stop the request handling flow.
+		// when the state is `Deny`, tell the client how long to wait and return a 429 response,
+		// stopping the request handling flow.
 		if result.State == Deny {
+			res.Header().Set(retryAfter, strconv.FormatInt(int64(result.RetryAfter.Seconds()), 10))
 			h.writeResponse(res, http.StatusTooManyRequests, "you have sent too many requests to this service, slow down please :)")
 			return
 		}
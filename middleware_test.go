@@ -0,0 +1,105 @@
+package ratelimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyBypassAcceptsAMatchingKey(t *testing.T) {
+	bypass := APIKeyBypass("X-API-Key", NewStaticKeyProvider("secret-one", "secret-two"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-API-Key", "secret-two")
+
+	if !bypass(req) {
+		t.Fatal("expected a matching API key to bypass rate limiting")
+	}
+}
+
+func TestAPIKeyBypassRejectsAMissingOrUnknownKey(t *testing.T) {
+	bypass := APIKeyBypass("X-API-Key", NewStaticKeyProvider("secret-one"))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if bypass(req) {
+		t.Fatal("expected a request without the header to not bypass rate limiting")
+	}
+
+	req.Header.Set("X-API-Key", "wrong-key")
+	if bypass(req) {
+		t.Fatal("expected an unknown API key to not bypass rate limiting")
+	}
+}
+
+func TestRateLimitingMiddlewareSkipsRateLimitingOnBypass(t *testing.T) {
+	called := false
+	handler := &httpRateLimiterHandler{config: &RateLimiterConfig{
+		Extractor: NewHTTPHeadersExtractor("X-User-Id"),
+		Strategy:  NewCounterStrategy(NewMemoryStore(), time.Now),
+		Bypass:    func(r *http.Request) bool { return true },
+	}}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	res := httptest.NewRecorder()
+	handler.RateLimitingMiddleware(next).ServeHTTP(res, req)
+
+	if !called {
+		t.Fatal("expected the wrapped handler to be called when bypassed")
+	}
+	if got := res.Header().Get(rateLimitingState); got != rateLimitingBypassState {
+		t.Fatalf("expected the %s header to report a bypass, got %q", rateLimitingState, got)
+	}
+}
+
+func TestRateLimitingMiddlewareSetsRateLimitHeadersOnAllow(t *testing.T) {
+	handler := &httpRateLimiterHandler{config: &RateLimiterConfig{
+		Extractor:   NewHTTPHeadersExtractor("X-User-Id"),
+		Strategy:    NewCounterStrategy(NewMemoryStore(), time.Now),
+		MaxRequests: 2,
+	}}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User-Id", "user-1")
+	res := httptest.NewRecorder()
+	handler.RateLimitingMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("expected the first request to be allowed, got status %d", res.Code)
+	}
+	if got := res.Header().Get(rateLimitLimit); got != "2" {
+		t.Fatalf("expected %s to be 2, got %q", rateLimitLimit, got)
+	}
+	if got := res.Header().Get(rateLimitRemaining); got != "1" {
+		t.Fatalf("expected %s to be 1 after the first of 2 requests, got %q", rateLimitRemaining, got)
+	}
+}
+
+func TestRateLimitingMiddlewareSetsRetryAfterOnDeny(t *testing.T) {
+	handler := &httpRateLimiterHandler{config: &RateLimiterConfig{
+		Extractor:   NewHTTPHeadersExtractor("X-User-Id"),
+		Strategy:    NewCounterStrategy(NewMemoryStore(), time.Now),
+		Expiration:  time.Minute,
+		MaxRequests: 1,
+	}}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User-Id", "user-1")
+	handler.RateLimitingMiddleware(next).ServeHTTP(httptest.NewRecorder(), req)
+
+	res := httptest.NewRecorder()
+	handler.RateLimitingMiddleware(next).ServeHTTP(res, req)
+
+	if res.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected the second request to be denied with 429, got status %d", res.Code)
+	}
+	if got := res.Header().Get(rateLimitRemaining); got != "0" {
+		t.Fatalf("expected %s to be clamped to 0 on Deny, got %q", rateLimitRemaining, got)
+	}
+	if res.Header().Get(retryAfter) == "" {
+		t.Fatalf("expected a %s header on Deny", retryAfter)
+	}
+}
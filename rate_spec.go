@@ -0,0 +1,67 @@
+package ratelimiter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RateSpec is a parsed "<limit>-<period>" rate, e.g. "10-S" for 10 requests per second.
+// It's the building block `ParseRate` produces so a `RateLimiterConfig` can be handed a slice
+// of tiers (e.g. 60 req/min *and* 1000 req/hour) instead of a single MaxRequests/Expiration pair.
+type RateSpec struct {
+	Limit    uint64
+	Duration time.Duration
+
+	raw string
+}
+
+// String returns the spec in the same "<limit>-<period>" form `ParseRate` accepts. A RateSpec
+// built directly whose Duration matches none of the periods is written as "<limit>-<duration>"
+// instead (e.g. "10-1m30s"), which `ParseRate` does not accept.
+func (s RateSpec) String() string {
+	if s.raw != "" {
+		return s.raw
+	}
+	limit := strconv.FormatUint(s.Limit, 10)
+	for period, duration := range periodDurations {
+		if duration == s.Duration {
+			return limit + "-" + period
+		}
+	}
+	return limit + "-" + s.Duration.String()
+}
+
+// periodDurations maps the period suffix `ParseRate` accepts to the Duration it represents.
+var periodDurations = map[string]time.Duration{
+	"S": time.Second,
+	"M": time.Minute,
+	"H": time.Hour,
+	"D": 24 * time.Hour,
+}
+
+// ParseRate parses a rate of the form "<limit>-<period>", where period is one of `S`, `M`, `H`
+// or `D` for second, minute, hour or day, e.g. `ParseRate("10-S")`, `ParseRate("1000-H")`.
+func ParseRate(rate string) (RateSpec, error) {
+	limitPart, periodPart, ok := strings.Cut(rate, "-")
+	if !ok {
+		return RateSpec{}, errors.Errorf("invalid rate %q: expected the form <limit>-<period>", rate)
+	}
+
+	limit, err := strconv.ParseUint(limitPart, 10, 64)
+	if err != nil {
+		return RateSpec{}, errors.Wrapf(err, "invalid rate %q: limit must be a positive integer", rate)
+	}
+	if limit == 0 {
+		return RateSpec{}, errors.Errorf("invalid rate %q: limit must be a positive integer", rate)
+	}
+
+	duration, ok := periodDurations[strings.ToUpper(periodPart)]
+	if !ok {
+		return RateSpec{}, errors.Errorf("invalid rate %q: period must be one of S, M, H, D", rate)
+	}
+
+	return RateSpec{Limit: limit, Duration: duration, raw: rate}, nil
+}
@@ -0,0 +1,53 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRateAcceptsEveryPeriod(t *testing.T) {
+	cases := []struct {
+		rate     string
+		limit    uint64
+		duration time.Duration
+	}{
+		{"10-S", 10, time.Second},
+		{"60-M", 60, time.Minute},
+		{"1000-H", 1000, time.Hour},
+		{"5000-D", 5000, 24 * time.Hour},
+		{"10-s", 10, time.Second},
+	}
+
+	for _, c := range cases {
+		spec, err := ParseRate(c.rate)
+		if err != nil {
+			t.Fatalf("ParseRate(%q): unexpected error: %v", c.rate, err)
+		}
+		if spec.Limit != c.limit {
+			t.Fatalf("ParseRate(%q): expected limit %d, got %d", c.rate, c.limit, spec.Limit)
+		}
+		if spec.Duration != c.duration {
+			t.Fatalf("ParseRate(%q): expected duration %v, got %v", c.rate, c.duration, spec.Duration)
+		}
+	}
+}
+
+func TestParseRateRejectsMalformedInput(t *testing.T) {
+	cases := []string{"", "10", "10-", "-S", "ten-S", "10-W"}
+
+	for _, rate := range cases {
+		if _, err := ParseRate(rate); err == nil {
+			t.Fatalf("ParseRate(%q): expected an error, got nil", rate)
+		}
+	}
+}
+
+func TestRateSpecStringRoundTrips(t *testing.T) {
+	spec, err := ParseRate("60-M")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.String() != "60-M" {
+		t.Fatalf("expected String() to return the original spec, got %q", spec.String())
+	}
+}
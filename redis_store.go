@@ -0,0 +1,110 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+var (
+	_ Store     = &redisStore{}
+	_ Pipeliner = &redisPipeliner{}
+	_ IntResult = &redisIntResult{}
+)
+
+type redisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore wraps a `redis.UniversalClient` as a Store, so it works unmodified with a
+// plain `*redis.Client`, a `*redis.ClusterClient`, or a Sentinel-backed client, since all three
+// satisfy that interface.
+func NewRedisStore(client redis.UniversalClient) *redisStore {
+	return &redisStore{
+		client: client,
+	}
+}
+
+func (s *redisStore) CountBetween(ctx context.Context, key, min, max string) (uint64, error) {
+	return s.client.ZCount(ctx, key, min, max).Uint64()
+}
+
+func (s *redisStore) AddToSet(ctx context.Context, key string, score float64, member string) error {
+	return s.client.ZAdd(ctx, key, redis.Z{Score: score, Member: member}).Err()
+}
+
+func (s *redisStore) RemoveRangeByScore(ctx context.Context, key, min, max string) error {
+	return s.client.ZRemRangeByScore(ctx, key, min, max).Err()
+}
+
+func (s *redisStore) RemoveMember(ctx context.Context, key, member string) error {
+	return s.client.ZRem(ctx, key, member).Err()
+}
+
+func (s *redisStore) Pipeline() Pipeliner {
+	return &redisPipeliner{pipe: s.client.Pipeline()}
+}
+
+func (s *redisStore) Incr(ctx context.Context, key string) (int64, error) {
+	return s.client.Incr(ctx, key).Result()
+}
+
+func (s *redisStore) Get(ctx context.Context, key string) (string, error) {
+	value, err := s.client.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return "", ErrKeyNotFound
+	}
+	return value, err
+}
+
+func (s *redisStore) Set(ctx context.Context, key, value string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Eval runs `script.Lua` via `EVAL`, relying on redis to keep the read-modify-write atomic
+// even when `client` is a `*redis.ClusterClient`.
+func (s *redisStore) Eval(ctx context.Context, script Script, keys []string, args ...interface{}) ([]interface{}, error) {
+	reply, err := redis.NewScript(script.Lua).Run(ctx, s.client, keys, args...).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if values, ok := reply.([]interface{}); ok {
+		return values, nil
+	}
+	return []interface{}{reply}, nil
+}
+
+type redisIntResult struct {
+	cmd *redis.IntCmd
+}
+
+func (r *redisIntResult) Result() (int64, error) {
+	return r.cmd.Result()
+}
+
+func (r *redisIntResult) Err() error {
+	return r.cmd.Err()
+}
+
+type redisPipeliner struct {
+	pipe redis.Pipeliner
+}
+
+func (p *redisPipeliner) RemoveRangeByScore(ctx context.Context, key, min, max string) IntResult {
+	return &redisIntResult{cmd: p.pipe.ZRemRangeByScore(ctx, key, min, max)}
+}
+
+func (p *redisPipeliner) AddToSet(ctx context.Context, key string, score float64, member string) IntResult {
+	return &redisIntResult{cmd: p.pipe.ZAdd(ctx, key, redis.Z{Score: score, Member: member})}
+}
+
+func (p *redisPipeliner) CountBetween(ctx context.Context, key, min, max string) IntResult {
+	return &redisIntResult{cmd: p.pipe.ZCount(ctx, key, min, max)}
+}
+
+func (p *redisPipeliner) Exec(ctx context.Context) error {
+	_, err := p.pipe.Exec(ctx)
+	return err
+}
@@ -5,7 +5,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/go-redis/redis/v9"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 )
@@ -21,14 +20,17 @@ const (
 )
 
 type counterStrategy struct {
-	client *redis.ClusterClient
-	now    func() time.Time
+	store Store
+	now   func() time.Time
 }
 
-func NewCounterStrategy(client *redis.ClusterClient, now func() time.Time) *counterStrategy {
+// NewCounterStrategy creates a Strategy backed by a rolling window sorted set. It takes a
+// Store instead of a concrete redis client so callers can plug in a `NewMemoryStore` for tests
+// or single-instance deployments without spinning up a redis.
+func NewCounterStrategy(store Store, now func() time.Time) *counterStrategy {
 	return &counterStrategy{
-		client: client,
-		now:    now,
+		store: store,
+		now:   now,
 	}
 }
 
@@ -51,35 +53,34 @@ func (s *counterStrategy) Run(ctx context.Context, r *Request) (*Result, error)
 	// If the client continues to send requests it also means that the memory for this specific key will not be reclaimed
 	// (as we're not writing data here) so make sure there is an eviction policy that will clear up the
 	// memory if the redis starts to get close to it's memory limit.
-	result, err := s.client.ZCount(ctx, r.Key, strconv.FormatInt(minimum.UnixMilli(), 10), sortedSetMax).Uint64()
-	if err != nil && result >= r.Limit {
+	result, err := s.store.CountBetween(ctx, r.Key, strconv.FormatInt(minimum.UnixMilli(), 10), sortedSetMax)
+	if err == nil && result >= r.Limit {
 		return &Result{
 			State:         Deny,
 			TotalRequests: result,
 			ExpiresAt:     expiresAt,
+			RetryAfter:    r.Duration,
+			Limit:         r.Limit,
 		}, nil
 	}
 
 	// every request needs a UUID
 	item := uuid.New()
 
-	// We're using a pipeline to speed up process by packing operations to batches and sending them at once to redis and read
-	// replies in a single step.
-	p := s.client.Pipeline()
+	// We're using a pipeline to speed up process by packing operations to batches and sending them at once to the
+	// store and read replies in a single step.
+	p := s.store.Pipeline()
 
 	// We can remove all requests that have already expired on this set
-	removeByScore := p.ZRemRangeByScore(ctx, r.Key, "0", strconv.FormatInt(minimum.UnixMilli(), 10))
+	removeByScore := p.RemoveRangeByScore(ctx, r.Key, "0", strconv.FormatInt(minimum.UnixMilli(), 10))
 
 	// We add the current request
-	add := p.ZAdd(ctx, r.Key, redis.Z{
-		Score:  float64(now.UnixMilli()),
-		Member: item.String(),
-	})
+	add := p.AddToSet(ctx, r.Key, float64(now.UnixMilli()), item.String())
 
 	// count how many non-expired requests we have on the sorted set
-	count := p.ZCount(ctx, r.Key, sortedSetMin, sortedSetMax)
+	count := p.CountBetween(ctx, r.Key, sortedSetMin, sortedSetMax)
 
-	if _, err := p.Exec(ctx); err != nil {
+	if err := p.Exec(ctx); err != nil {
 		return nil, errors.Wrapf(err, "failed to execute sorted set pipeline for key: %v", r.Key)
 	}
 
@@ -103,6 +104,8 @@ func (s *counterStrategy) Run(ctx context.Context, r *Request) (*Result, error)
 			State:         Deny,
 			TotalRequests: requests,
 			ExpiresAt:     expiresAt,
+			RetryAfter:    r.Duration,
+			Limit:         r.Limit,
 		}, nil
 	}
 
@@ -110,5 +113,11 @@ func (s *counterStrategy) Run(ctx context.Context, r *Request) (*Result, error)
 		State:         Allow,
 		TotalRequests: requests,
 		ExpiresAt:     expiresAt,
+		Limit:         r.Limit,
+		rollback: func(ctx context.Context) error {
+			// scores aren't unique (two requests landing in the same millisecond share one), so
+			// undoing this specific request has to key off its UUID member, not its score.
+			return s.store.RemoveMember(ctx, r.Key, item.String())
+		},
 	}, nil
 }
@@ -0,0 +1,95 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCounterStrategyAllowsUpToLimitThenDenies(t *testing.T) {
+	now := time.Now()
+	strategy := NewCounterStrategy(NewMemoryStore(), func() time.Time { return now })
+	req := &Request{Key: "client", Limit: 2, Duration: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		result, err := strategy.Run(context.Background(), req)
+		if err != nil {
+			t.Fatalf("request %d: unexpected error: %v", i, err)
+		}
+		if result.State != Allow {
+			t.Fatalf("request %d: expected Allow, got %v", i, result.State)
+		}
+	}
+
+	result, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.State != Deny {
+		t.Fatalf("expected the 3rd request within the window to Deny, got %v", result.State)
+	}
+	if result.RetryAfter <= 0 {
+		t.Fatalf("expected a positive RetryAfter on Deny, got %v", result.RetryAfter)
+	}
+}
+
+func TestCounterStrategyDeniesOnTheFastPathWithoutWriting(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	strategy := NewCounterStrategy(store, func() time.Time { return now })
+	req := &Request{Key: "client", Limit: 1, Duration: time.Minute}
+
+	if _, err := strategy.Run(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := strategy.Run(context.Background(), req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count, err := store.CountBetween(context.Background(), req.Key, sortedSetMin, sortedSetMax)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected the fast-path deny to skip writing another member, got %d members", count)
+	}
+}
+
+func TestCounterStrategyRollbackRemovesOnlyItsOwnMember(t *testing.T) {
+	now := time.Now()
+	strategy := NewCounterStrategy(NewMemoryStore(), func() time.Time { return now })
+	req := &Request{Key: "client", Limit: 2, Duration: time.Minute}
+
+	// two requests landing in the exact same millisecond, so they share a score.
+	first, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.State != Allow {
+		t.Fatalf("expected the first request to Allow, got %v", first.State)
+	}
+
+	second, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if second.State != Allow {
+		t.Fatalf("expected the second request to Allow, got %v", second.State)
+	}
+
+	if err := first.rollback(context.Background()); err != nil {
+		t.Fatalf("unexpected rollback error: %v", err)
+	}
+
+	result, err := strategy.Run(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.State != Allow {
+		t.Fatalf("expected a third request to Allow after the rollback freed a slot, got %v", result.State)
+	}
+	if result.TotalRequests != 2 {
+		t.Fatalf("expected the rollback to remove only its own member, leaving 2 members, got %d", result.TotalRequests)
+	}
+}
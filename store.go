@@ -0,0 +1,63 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrKeyNotFound is returned by a Store's `Get` when the key has no value, or its value has
+// already expired, regardless of which backend is behind the Store.
+var ErrKeyNotFound = errors.New("ratelimiter: key not found")
+
+// IntResult is the reply to an operation queued on a Pipeliner. Callers must call `Exec` on
+// the Pipeliner before reading a `Result`, mirroring how a `*redis.IntCmd` behaves.
+type IntResult interface {
+	Result() (int64, error)
+	Err() error
+}
+
+// Pipeliner batches several sorted-set operations together so a Store only needs a single
+// round trip to apply them, the same way `counterStrategy` batches its writes today.
+type Pipeliner interface {
+	RemoveRangeByScore(ctx context.Context, key, min, max string) IntResult
+	AddToSet(ctx context.Context, key string, score float64, member string) IntResult
+	CountBetween(ctx context.Context, key, min, max string) IntResult
+	Exec(ctx context.Context) error
+}
+
+// Script is an atomic read-modify-write operation a Strategy can hand to a Store, such as the
+// GCRA's "bump the TAT and tell me if that's still within the burst tolerance". `Lua` is run
+// against a redis backend via `EVAL` so the whole operation stays atomic even on a cluster,
+// and `Exec` is the equivalent operation run while a MemoryStore holds the key's shard lock,
+// so the same Strategy works unmodified against either backend.
+type Script struct {
+	Lua  string
+	Exec func(now time.Time, get func() (string, bool), set func(value string, ttl time.Duration), args []interface{}) []interface{}
+}
+
+// Store is the set of primitives a Strategy needs from its backing datastore. It exists so
+// strategies aren't hard-wired to a specific redis client, which makes it possible to plug in
+// an in-process store for tests or single-instance deployments, mirroring the "store" approach
+// used by libraries such as ulule/limiter.
+//
+// `CountBetween`, `AddToSet`, `RemoveRangeByScore`, `RemoveMember` and `Pipeline` expose the
+// sorted-set primitives the rolling window counter strategy needs. `Incr`, `Get`, `Set` and
+// `Eval` are generic enough for strategies that only ever need a single value per key, such as
+// GCRA or a token bucket.
+type Store interface {
+	CountBetween(ctx context.Context, key, min, max string) (uint64, error)
+	AddToSet(ctx context.Context, key string, score float64, member string) error
+	RemoveRangeByScore(ctx context.Context, key, min, max string) error
+	// RemoveMember removes a single member from the sorted set, regardless of its score. Scores
+	// aren't unique, so this is the only way to undo one specific `AddToSet` without risking
+	// deleting unrelated members that happen to share its score.
+	RemoveMember(ctx context.Context, key, member string) error
+	Pipeline() Pipeliner
+
+	Incr(ctx context.Context, key string) (int64, error)
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+	Eval(ctx context.Context, script Script, keys []string, args ...interface{}) ([]interface{}, error)
+}
@@ -0,0 +1,101 @@
+package ratelimiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreIncrAndGetRespectTTL(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if _, err := store.Incr(ctx, "counter"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	count, err := store.Incr(ctx, "counter")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected the counter to be 2, got %d", count)
+	}
+
+	if err := store.Set(ctx, "key", "value", time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if _, err := store.Get(ctx, "key"); err != ErrKeyNotFound {
+		t.Fatalf("expected ErrKeyNotFound once the TTL elapsed, got %v", err)
+	}
+}
+
+func TestMemoryStoreAddToSetIsIdempotentPerMember(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if err := store.AddToSet(ctx, "set", 1, "item"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// re-adding the same member should update its score, not create a second entry.
+	if err := store.AddToSet(ctx, "set", 2, "item"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count, err := store.CountBetween(ctx, "set", sortedSetMin, sortedSetMax)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected re-adding the same member to leave 1 entry, got %d", count)
+	}
+}
+
+func TestMemoryStoreRemoveMemberLeavesOtherMembersWithTheSameScore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if err := store.AddToSet(ctx, "set", 1, "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.AddToSet(ctx, "set", 1, "b"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.RemoveMember(ctx, "set", "a"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count, err := store.CountBetween(ctx, "set", sortedSetMin, sortedSetMax)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected RemoveMember to only remove its own member, got %d members left", count)
+	}
+}
+
+func TestMemoryStoreRemoveRangeByScoreDropsExpiredEntries(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	if err := store.AddToSet(ctx, "set", 1, "old"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := store.AddToSet(ctx, "set", 100, "new"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := store.RemoveRangeByScore(ctx, "set", "0", "50"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	count, err := store.CountBetween(ctx, "set", sortedSetMin, sortedSetMax)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected only the expired entry to be removed, got %d members left", count)
+	}
+}